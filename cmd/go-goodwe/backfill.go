@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrtimp/go-goodwe/pkg/buffer"
+	"github.com/mrtimp/go-goodwe/pkg/pvoutput"
+)
+
+// BackfillCommand uploads readings left in the on-disk buffer (--state-dir)
+// that a prior run wasn't able to post live, via PVOutput's batch endpoint.
+type BackfillCommand struct {
+	Since string `long:"since" description:"Only backfill readings from this date onward (YYYY-MM-DD); defaults to everything pending"`
+}
+
+// Execute implements go-flags' Commander interface.
+func (b *BackfillCommand) Execute(args []string) error {
+	since := time.Time{}
+	if b.Since != "" {
+		t, err := time.Parse("2006-01-02", b.Since)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: %w", b.Since, err)
+		}
+		since = t
+	}
+
+	store, err := buffer.NewStore(opts.StateDir)
+	if err != nil {
+		return err
+	}
+
+	pvo := pvoutput.NewClient(pvoutput.Config{
+		APIKey:   opts.ApiKey,
+		SystemID: opts.SystemID,
+	})
+
+	uploaded, err := buffer.FlushSince(store, pvo, since)
+	if err != nil {
+		return fmt.Errorf("backfill: uploaded %d readings before failing: %w", uploaded, err)
+	}
+
+	fmt.Printf("Backfilled %d readings\n", uploaded)
+
+	return nil
+}
@@ -0,0 +1,379 @@
+// Command go-goodwe polls a GoodWe inverter over UDP and uploads readings
+// to PVOutput on a schedule aligned to PVOutput's 5-minute status buckets.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/nathan-osman/go-sunrise"
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mrtimp/go-goodwe/pkg/buffer"
+	"github.com/mrtimp/go-goodwe/pkg/geocode"
+	"github.com/mrtimp/go-goodwe/pkg/goodwe"
+	"github.com/mrtimp/go-goodwe/pkg/metrics"
+	"github.com/mrtimp/go-goodwe/pkg/mqtt"
+	"github.com/mrtimp/go-goodwe/pkg/pvoutput"
+)
+
+// prefetchLead is how long before a PVOutput status boundary we query the
+// inverter, so the reading is already in hand when it's time to upload.
+const prefetchLead = 20 * time.Second
+
+// prefetchTimeout bounds how long the prefetch job may spend retrying the
+// inverter before giving up for this cycle.
+const prefetchTimeout = 45 * time.Second
+
+// backlogFlushSpec re-attempts any readings still sitting in the on-disk
+// buffer, so an extended network outage doesn't require a restart or a
+// manual `backfill` to clear once connectivity returns.
+const backlogFlushSpec = "0 */15 * * * *"
+
+type Options struct {
+	ApiKey string `short:"a" long:"api-key" description:"The PVOutput API key" env:"API_KEY" required:"true"`
+	Debug  bool   `short:"d" long:"debug" description:"Show debug output"`
+	// IpAddress and Location are only required for the default daemon mode;
+	// go-flags enforces required:"true" even when the backfill subcommand is
+	// active, so they're validated by hand in main() instead.
+	IpAddress string `short:"i" long:"ip-address" description:"The IP address of the GoodWe inverter" env:"IP_ADDRESS"`
+	Port      int    `short:"p" long:"port" description:"The port that the GoodWe inverter is listening on" default:"8899" env:"PORT"`
+	SystemID  string `short:"s" long:"system-id" description:"The PVOutput System ID" env:"SYSTEM_ID" required:"true"`
+	Location  string `short:"l" long:"location" description:"Location (city, country)" env:"LOCATION"`
+	Interval  string `long:"interval" description:"Polling interval, aligned to PVOutput's status buckets (e.g. 5m, 10m, 15m)" default:"5m" env:"INTERVAL"`
+	StateDir  string `long:"state-dir" description:"Directory for the on-disk reading buffer, used to backfill failed uploads" default:".go-goodwe-state" env:"STATE_DIR"`
+
+	MQTTBroker   string `long:"mqtt-broker" description:"MQTT broker URL (e.g. tcp://host:1883, ssl://host:8883); enables MQTT publishing when set" env:"MQTT_BROKER"`
+	MQTTTopic    string `long:"mqtt-topic" description:"Base MQTT topic for publishing inverter state" default:"goodwe" env:"MQTT_TOPIC"`
+	MQTTUsername string `long:"mqtt-username" description:"MQTT broker username" env:"MQTT_USERNAME"`
+	MQTTPassword string `long:"mqtt-password" description:"MQTT broker password" env:"MQTT_PASSWORD"`
+	MQTTQoS      uint8  `long:"mqtt-qos" description:"MQTT QoS level (0, 1 or 2)" default:"0" env:"MQTT_QOS"`
+	MQTTRetain   bool   `long:"mqtt-retain" description:"Retain MQTT state messages" env:"MQTT_RETAIN"`
+
+	MetricsAddr     string `long:"metrics-addr" description:"Address to serve Prometheus /metrics on (e.g. :9090); disabled unless set" env:"METRICS_ADDR"`
+	MetricsOnScrape bool   `long:"metrics-on-scrape" description:"Query the inverter on every /metrics scrape instead of serving the last polled reading" env:"METRICS_ON_SCRAPE"`
+}
+
+var opts Options
+
+var backfillCmd BackfillCommand
+
+func main() {
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.SubcommandsOptional = true // default daemon mode runs without a subcommand
+	if _, err := parser.AddCommand("backfill", "Upload buffered readings via PVOutput's batch endpoint",
+		"Upload any readings left in --state-dir that a prior run couldn't post live, oldest first.", &backfillCmd); err != nil {
+		log.Fatalf("Failed to register backfill command: %v\n", err)
+	}
+
+	if _, err := parser.Parse(); err != nil {
+		os.Exit(1)
+	}
+
+	if parser.Active != nil {
+		return // a subcommand ran via its Execute method
+	}
+
+	if opts.IpAddress == "" {
+		fmt.Println("the required flag `-i, --ip-address' was not specified")
+		os.Exit(1)
+	}
+	if opts.Location == "" {
+		fmt.Println("the required flag `-l, --location' was not specified")
+		os.Exit(1)
+	}
+
+	log.SetOutput(os.Stderr)
+
+	if opts.Debug {
+		log.SetLevel(log.DebugLevel)
+	} else {
+		log.SetLevel(log.WarnLevel)
+	}
+
+	cachePath := ".location_cache.json"
+	cache, err := geocode.LoadCache(cachePath)
+	if err != nil {
+		log.Warnf("Failed to load location cache %q, starting with an empty one: %v\n", cachePath, err)
+		cache = make(geocode.LocationCache)
+	}
+
+	lat, lon, err := geocode.Resolve(geocode.NewNominatimGeocoder(), cache, cachePath, opts.Location)
+	if err != nil {
+		fmt.Printf("Geocoding error: %v\n", err)
+		os.Exit(1)
+	}
+
+	interval, err := time.ParseDuration(opts.Interval)
+	if err != nil {
+		log.Fatalf("Invalid --interval %q: %v\n", opts.Interval, err)
+	}
+
+	store, err := buffer.NewStore(opts.StateDir)
+	if err != nil {
+		log.Fatalf("Failed to open state dir %q: %v\n", opts.StateDir, err)
+	}
+
+	d := &daemon{
+		client: goodwe.New(opts.IpAddress, opts.Port),
+		pvo: pvoutput.NewClient(pvoutput.Config{
+			APIKey:   opts.ApiKey,
+			SystemID: opts.SystemID,
+		}),
+		buffer:   store,
+		lat:      lat,
+		lon:      lon,
+		interval: interval,
+		debug:    opts.Debug,
+	}
+
+	if opts.MQTTBroker != "" {
+		pub, err := mqtt.NewPublisher(mqtt.Config{
+			Broker:   opts.MQTTBroker,
+			ClientID: "go-goodwe",
+			Username: opts.MQTTUsername,
+			Password: opts.MQTTPassword,
+			Topic:    opts.MQTTTopic,
+			QoS:      opts.MQTTQoS,
+			Retain:   opts.MQTTRetain,
+		}, opts.IpAddress)
+		if err != nil {
+			log.Fatalf("Failed to connect to MQTT broker: %v\n", err)
+		}
+		if err := pub.PublishDiscovery(); err != nil {
+			log.Fatalf("Failed to publish MQTT discovery configs: %v\n", err)
+		}
+
+		d.mqtt = pub
+	}
+
+	if opts.MetricsAddr != "" {
+		ms := metrics.NewServer(d.client, opts.MetricsOnScrape)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", ms)
+
+		go func() {
+			if err := http.ListenAndServe(opts.MetricsAddr, mux); err != nil {
+				log.Fatalf("Metrics server failed: %v\n", err)
+			}
+		}()
+
+		d.metrics = ms
+	}
+
+	d.refreshSunTimes(time.Now())
+
+	d.run()
+}
+
+// daemon polls the inverter on a schedule aligned to PVOutput's 5-minute
+// status buckets (00, 05, 10, ...) and uploads each reading as it lands.
+// It prefetches the inverter reading shortly before each boundary so the
+// upload itself doesn't have to wait on a slow UDP round trip.
+type daemon struct {
+	client   *goodwe.Client
+	pvo      *pvoutput.Client
+	mqtt     *mqtt.Publisher // nil unless --mqtt-broker is set
+	buffer   *buffer.Store
+	metrics  *metrics.Server // nil unless --metrics-addr is set
+	lat, lon float64
+	interval time.Duration
+	debug    bool
+
+	mu         sync.Mutex
+	sunrise    time.Time
+	sunset     time.Time
+	cachedData *goodwe.Data
+	cachedErr  error
+}
+
+// refreshSunTimes recomputes today's sunrise/sunset for the configured
+// location. It's called once at startup and again every day at 00:05.
+func (d *daemon) refreshSunTimes(now time.Time) {
+	year, month, day := now.Date()
+	sunriseTime, sunsetTime := sunrise.SunriseSunset(d.lat, d.lon, year, month, day)
+
+	d.mu.Lock()
+	d.sunrise, d.sunset = sunriseTime, sunsetTime
+	d.mu.Unlock()
+
+	log.Debugf("Sunrise: %v, sunset: %v\n", sunriseTime, sunsetTime)
+}
+
+func (d *daemon) daylight(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !now.Before(d.sunrise) && !now.After(d.sunset)
+}
+
+// run starts the cron scheduler and blocks forever. A prefetch job queries
+// the inverter shortly before each status boundary; the upload job then
+// POSTs whatever the prefetch cached so `t=HH:MM` lines up cleanly.
+func (d *daemon) run() {
+	d.flushBacklog()
+
+	c := cron.New(cron.WithSeconds())
+
+	if _, err := c.AddFunc("0 5 0 * * *", func() { d.refreshSunTimes(time.Now()) }); err != nil {
+		log.Fatalf("Failed to schedule sun-times refresh: %v\n", err)
+	}
+
+	if _, err := c.AddFunc(backlogFlushSpec, d.flushBacklog); err != nil {
+		log.Fatalf("Failed to schedule backlog flush: %v\n", err)
+	}
+
+	prefetchSpec, uploadSpec, err := alignedCronSpecs(d.interval, prefetchLead)
+	if err != nil {
+		log.Fatalf("Invalid --interval %v: %v\n", d.interval, err)
+	}
+
+	if _, err := c.AddFunc(prefetchSpec, d.prefetch); err != nil {
+		log.Fatalf("Failed to schedule prefetch job: %v\n", err)
+	}
+
+	if _, err := c.AddFunc(uploadSpec, d.uploadCached); err != nil {
+		log.Fatalf("Failed to schedule upload job: %v\n", err)
+	}
+
+	log.Infof("Starting daemon: interval=%v prefetch=%q upload=%q\n", d.interval, prefetchSpec, uploadSpec)
+
+	c.Run()
+}
+
+// flushBacklog re-attempts any readings still sitting in the buffer. It
+// runs once at startup and again on backlogFlushSpec, so readings left by a
+// failed live upload (see uploadCached) don't wait on a restart or a manual
+// `backfill` to clear once the network recovers.
+func (d *daemon) flushBacklog() {
+	if uploaded, err := buffer.Flush(d.buffer, d.pvo); err != nil {
+		log.Warnf("Backlog flush failed after uploading %d readings: %v\n", uploaded, err)
+	} else if uploaded > 0 {
+		log.Infof("Flushed %d buffered readings\n", uploaded)
+	}
+}
+
+// prefetch queries the inverter and caches the result for the upload job
+// that follows shortly after. It's a no-op outside daylight hours.
+func (d *daemon) prefetch() {
+	now := time.Now()
+	if !d.daylight(now) {
+		log.Debug("Prefetch skipped, outside daylight hours")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+	defer cancel()
+
+	data, err := d.client.GetData(ctx)
+
+	d.mu.Lock()
+	d.cachedData, d.cachedErr = data, err
+	d.mu.Unlock()
+
+	if d.metrics != nil {
+		d.metrics.Update(data, err)
+	}
+
+	if err != nil {
+		log.Warnf("Prefetch failed: %v\n", err)
+		return
+	}
+
+	if d.debug {
+		jsonData, mErr := json.MarshalIndent(data, "", "  ")
+		if mErr == nil {
+			log.Debug(string(jsonData))
+		}
+	}
+}
+
+// uploadCached posts whatever prefetch last cached. It's a no-op outside
+// daylight hours or if the prefetch that should have populated the cache
+// failed or hasn't run yet.
+func (d *daemon) uploadCached() {
+	now := time.Now()
+	if !d.daylight(now) {
+		log.Debug("Upload skipped, outside daylight hours")
+		return
+	}
+
+	d.mu.Lock()
+	data, err := d.cachedData, d.cachedErr
+	d.cachedData, d.cachedErr = nil, nil
+	d.mu.Unlock()
+
+	if err != nil {
+		log.Warnf("Skipping upload, prefetch failed: %v\n", err)
+		return
+	}
+
+	if data == nil {
+		log.Warn("Skipping upload, no prefetched reading available")
+		return
+	}
+
+	reading := pvoutput.Reading{
+		Date:        now,
+		Power:       int(data.PowerAC),
+		Energy:      int(data.YieldToday * 1000), // kWh → Wh
+		Voltage:     int(data.VoltageAC[0]),
+		Temperature: int(data.Temperature),
+	}
+
+	// Buffer before attempting the live POST, so the reading survives a
+	// failed upload or a crash mid-upload. A successful POST acks it
+	// immediately instead of waiting for the next backlog flush.
+	if err := d.buffer.Enqueue(reading); err != nil {
+		log.Errorf("Failed to buffer reading: %v\n", err)
+	}
+
+	if err := d.pvo.AddStatus(reading); err != nil {
+		log.Errorf("Upload to PVOutput failed, buffered for backfill: %v\n", err)
+	} else if err := d.buffer.Ack([]int64{reading.Date.UnixNano()}); err != nil {
+		log.Errorf("Failed to ack buffered reading: %v\n", err)
+	}
+
+	if d.mqtt != nil {
+		if err := d.mqtt.PublishData(data); err != nil {
+			log.Errorf("Failed to publish to MQTT: %v\n", err)
+		}
+	}
+}
+
+// alignedCronSpecs builds the pair of seconds-enabled cron specs needed to
+// run a prefetch `lead` before, and an upload exactly on, every boundary of
+// interval within the hour (e.g. interval=5m -> :00, :05, :10, ...).
+// interval must evenly divide 60 minutes.
+func alignedCronSpecs(interval time.Duration, lead time.Duration) (prefetchSpec, uploadSpec string, err error) {
+	minutes := int(interval.Minutes())
+	if minutes <= 0 || minutes > 60 || 60%minutes != 0 {
+		return "", "", fmt.Errorf("interval %v must evenly divide 60 minutes", interval)
+	}
+
+	leadSeconds := int(lead.Seconds())
+	if leadSeconds <= 0 || leadSeconds >= 60 {
+		return "", "", fmt.Errorf("prefetch lead %v must be between 0s and 60s", lead)
+	}
+
+	uploadMinutes := make([]string, 0, 60/minutes)
+	prefetchMinutes := make([]string, 0, 60/minutes)
+	for m := 0; m < 60; m += minutes {
+		uploadMinutes = append(uploadMinutes, strconv.Itoa(m))
+		prefetchMinutes = append(prefetchMinutes, strconv.Itoa((m+60-1)%60))
+	}
+
+	prefetchSpec = fmt.Sprintf("%d %s * * * *", 60-leadSeconds, strings.Join(prefetchMinutes, ","))
+	uploadSpec = fmt.Sprintf("0 %s * * * *", strings.Join(uploadMinutes, ","))
+
+	return prefetchSpec, uploadSpec, nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlignedCronSpecs(t *testing.T) {
+	prefetch, upload, err := alignedCronSpecs(5*time.Minute, 20*time.Second)
+	if err != nil {
+		t.Fatalf("alignedCronSpecs: %v", err)
+	}
+	if upload != "0 0,5,10,15,20,25,30,35,40,45,50,55 * * * *" {
+		t.Errorf("uploadSpec = %q", upload)
+	}
+	if prefetch != "40 59,4,9,14,19,24,29,34,39,44,49,54 * * * *" {
+		t.Errorf("prefetchSpec = %q", prefetch)
+	}
+}
+
+func TestAlignedCronSpecsRejectsUnevenInterval(t *testing.T) {
+	if _, _, err := alignedCronSpecs(7*time.Minute, 20*time.Second); err == nil {
+		t.Fatal("expected error for interval that doesn't evenly divide 60 minutes")
+	}
+}
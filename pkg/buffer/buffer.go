@@ -0,0 +1,146 @@
+// Package buffer stores PVOutput readings on disk before they're uploaded,
+// so a failed live POST or a daemon restart doesn't lose a sample. Pending
+// readings are later drained through PVOutput's batch endpoint.
+package buffer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mrtimp/go-goodwe/pkg/pvoutput"
+)
+
+// Record is one buffered reading awaiting upload. ID is derived from the
+// reading's timestamp, which makes acking idempotent across restarts: a
+// record already removed from the file simply won't be re-enqueued with a
+// matching ID.
+type Record struct {
+	ID      int64            `json:"id"`
+	Reading pvoutput.Reading `json:"reading"`
+}
+
+// Store is an append-only on-disk queue of Readings, backed by a single
+// JSON-lines file.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a Store backed by a pending.jsonl file under dir,
+// creating dir if it doesn't already exist.
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &Store{path: filepath.Join(dir, "pending.jsonl")}, nil
+}
+
+// Enqueue appends r to the buffer. Call this before attempting a live
+// upload, so the reading survives even if the upload, or the process
+// itself, fails.
+func (s *Store) Enqueue(r pvoutput.Reading) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b, err := json.Marshal(Record{ID: r.Date.UnixNano(), Reading: r})
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// Pending returns every buffered record that hasn't been Acked yet, oldest
+// first.
+func (s *Store) Pending() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.readAll()
+}
+
+func (s *Store) readAll() ([]Record, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+
+	return records, scanner.Err()
+}
+
+// Ack removes the given record IDs from the buffer, typically after
+// they've been successfully uploaded. It rewrites the file via a temp file
+// and rename so a crash mid-compaction can't corrupt or lose the remaining
+// pending records.
+func (s *Store) Ack(ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acked := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		acked[id] = true
+	}
+
+	records, err := s.readAll()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		if acked[rec.ID] {
+			continue
+		}
+
+		b, err := json.Marshal(rec)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if _, err := f.Write(append(b, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
@@ -0,0 +1,118 @@
+package buffer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrtimp/go-goodwe/pkg/pvoutput"
+)
+
+func TestStoreEnqueueAndPending(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	r1 := pvoutput.Reading{Date: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC), Power: 1000}
+	r2 := pvoutput.Reading{Date: time.Date(2026, 7, 26, 12, 5, 0, 0, time.UTC), Power: 1100}
+
+	if err := store.Enqueue(r1); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := store.Enqueue(r2); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	pending, err := store.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("len(pending) = %d, want 2", len(pending))
+	}
+	if pending[0].Reading.Power != 1000 || pending[1].Reading.Power != 1100 {
+		t.Errorf("pending out of order: %+v", pending)
+	}
+}
+
+func TestStorePendingOnFreshDir(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	pending, err := store.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("len(pending) = %d, want 0", len(pending))
+	}
+}
+
+func TestStoreAckRemovesOnlyAckedRecords(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	r1 := pvoutput.Reading{Date: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC), Power: 1000}
+	r2 := pvoutput.Reading{Date: time.Date(2026, 7, 26, 12, 5, 0, 0, time.UTC), Power: 1100}
+	_ = store.Enqueue(r1)
+	_ = store.Enqueue(r2)
+
+	pending, _ := store.Pending()
+	if err := store.Ack([]int64{pending[0].ID}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	remaining, err := store.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Reading.Power != 1100 {
+		t.Errorf("remaining = %+v, want only the 1100W record", remaining)
+	}
+}
+
+func TestStoreAckIsIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	r := pvoutput.Reading{Date: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC), Power: 1000}
+	_ = store.Enqueue(r)
+
+	pending, _ := store.Pending()
+	id := pending[0].ID
+
+	if err := store.Ack([]int64{id}); err != nil {
+		t.Fatalf("first Ack: %v", err)
+	}
+	if err := store.Ack([]int64{id}); err != nil {
+		t.Fatalf("second Ack (repeat): %v", err)
+	}
+
+	remaining, _ := store.Pending()
+	if len(remaining) != 0 {
+		t.Errorf("remaining = %+v, want empty", remaining)
+	}
+}
+
+func TestNewStoreUsesPendingFileUnderDir(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	want := filepath.Join(dir, "pending.jsonl")
+	if store.path != want {
+		t.Errorf("store.path = %q, want %q", store.path, want)
+	}
+}
@@ -0,0 +1,67 @@
+package buffer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrtimp/go-goodwe/pkg/pvoutput"
+)
+
+// BatchUploader uploads a batch of PVOutput readings in one call; satisfied
+// by *pvoutput.Client. Declaring it as an interface here lets Flush be
+// tested without a live (or fake HTTP) PVOutput client.
+type BatchUploader interface {
+	AddBatchStatus(readings []pvoutput.Reading) error
+}
+
+// Flush uploads every pending record via AddBatchStatus, in chunks of up to
+// pvoutput.MaxBatchSize, acking each chunk as it succeeds so a failure
+// partway through still makes forward progress. It returns the number of
+// records successfully uploaded.
+func Flush(store *Store, uploader BatchUploader) (int, error) {
+	return FlushSince(store, uploader, time.Time{})
+}
+
+// FlushSince is like Flush but only uploads records whose reading date is
+// at or after since. Pass the zero time to upload everything pending.
+func FlushSince(store *Store, uploader BatchUploader, since time.Time) (int, error) {
+	records, err := store.Pending()
+	if err != nil {
+		return 0, err
+	}
+
+	var filtered []Record
+	for _, rec := range records {
+		if !rec.Reading.Date.Before(since) {
+			filtered = append(filtered, rec)
+		}
+	}
+
+	uploaded := 0
+	for start := 0; start < len(filtered); start += pvoutput.MaxBatchSize {
+		end := start + pvoutput.MaxBatchSize
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		chunk := filtered[start:end]
+
+		readings := make([]pvoutput.Reading, len(chunk))
+		ids := make([]int64, len(chunk))
+		for i, rec := range chunk {
+			readings[i] = rec.Reading
+			ids[i] = rec.ID
+		}
+
+		if err := uploader.AddBatchStatus(readings); err != nil {
+			return uploaded, fmt.Errorf("flush batch starting at record %d: %w", start, err)
+		}
+
+		if err := store.Ack(ids); err != nil {
+			return uploaded, fmt.Errorf("ack batch starting at record %d: %w", start, err)
+		}
+
+		uploaded += len(chunk)
+	}
+
+	return uploaded, nil
+}
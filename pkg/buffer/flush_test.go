@@ -0,0 +1,125 @@
+package buffer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrtimp/go-goodwe/pkg/pvoutput"
+)
+
+var errBatchUploadFailed = errors.New("simulated batch upload failure")
+
+type fakeUploader struct {
+	batches [][]pvoutput.Reading
+	failOn  int // batch index (0-based) to fail, or -1 to always succeed
+}
+
+func (f *fakeUploader) AddBatchStatus(readings []pvoutput.Reading) error {
+	idx := len(f.batches)
+	f.batches = append(f.batches, readings)
+	if f.failOn == idx {
+		return errBatchUploadFailed
+	}
+	return nil
+}
+
+func TestFlushUploadsAndAcksPending(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	for i := 0; i < 35; i++ {
+		r := pvoutput.Reading{Date: time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute), Power: 1000 + i}
+		if err := store.Enqueue(r); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	uploader := &fakeUploader{failOn: -1}
+
+	uploaded, err := Flush(store, uploader)
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if uploaded != 35 {
+		t.Errorf("uploaded = %d, want 35", uploaded)
+	}
+	if len(uploader.batches) != 2 { // 30 + 5, since MaxBatchSize is 30
+		t.Errorf("batches posted = %d, want 2", len(uploader.batches))
+	}
+
+	remaining, err := store.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("remaining = %d, want 0", len(remaining))
+	}
+}
+
+func TestFlushSinceFiltersOlderReadings(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	old := pvoutput.Reading{Date: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), Power: 1000}
+	recent := pvoutput.Reading{Date: time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC), Power: 1100}
+	_ = store.Enqueue(old)
+	_ = store.Enqueue(recent)
+
+	uploader := &fakeUploader{failOn: -1}
+
+	uploaded, err := FlushSince(store, uploader, time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("FlushSince: %v", err)
+	}
+	if uploaded != 1 {
+		t.Fatalf("uploaded = %d, want 1", uploaded)
+	}
+
+	remaining, err := store.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Reading.Power != 1000 {
+		t.Errorf("remaining = %+v, want only the old, unfiltered record left", remaining)
+	}
+}
+
+func TestFlushStopsAtFirstFailedBatchButKeepsProgress(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	for i := 0; i < 40; i++ {
+		r := pvoutput.Reading{Date: time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute), Power: 1000 + i}
+		if err := store.Enqueue(r); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	uploader := &fakeUploader{failOn: 1} // second batch fails
+
+	uploaded, err := Flush(store, uploader)
+	if err == nil {
+		t.Fatal("expected error from failed batch")
+	}
+	if uploaded != 30 {
+		t.Errorf("uploaded = %d, want 30 (first batch only)", uploaded)
+	}
+
+	remaining, err := store.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(remaining) != 10 {
+		t.Errorf("remaining = %d, want 10", len(remaining))
+	}
+}
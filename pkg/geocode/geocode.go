@@ -0,0 +1,140 @@
+// Package geocode resolves a free-text location to latitude/longitude
+// coordinates, with an on-disk cache so repeated lookups don't hit the
+// upstream service.
+package geocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Geocoder resolves a location string to coordinates. Implementations may
+// call out to a remote service (Nominatim) or serve from a local/offline
+// dataset.
+type Geocoder interface {
+	Geocode(location string) (lat, lon float64, err error)
+}
+
+// LocationCache maps a location string to its resolved [lat, lon].
+type LocationCache map[string][2]float64
+
+// NominatimGeocoder resolves locations using the OpenStreetMap Nominatim
+// search API.
+type NominatimGeocoder struct {
+	HTTPClient *http.Client
+}
+
+// NewNominatimGeocoder returns a NominatimGeocoder using http.DefaultClient.
+func NewNominatimGeocoder() *NominatimGeocoder {
+	return &NominatimGeocoder{HTTPClient: http.DefaultClient}
+}
+
+type nominatimResult []struct {
+	Lat string `json:"lat"`
+	Lon string `json:"lon"`
+}
+
+// nominatimEndpoint is Nominatim's search endpoint. It's a var, rather than
+// a const, so tests can point it at an httptest server.
+var nominatimEndpoint = "https://nominatim.openstreetmap.org/search"
+
+// Geocode resolves location via Nominatim's /search endpoint.
+func (g *NominatimGeocoder) Geocode(location string) (float64, float64, error) {
+	params := url.Values{}
+	params.Set("q", location)
+	params.Set("format", "json")
+	params.Set("limit", "1")
+
+	reqURL := fmt.Sprintf("%s?%s", nominatimEndpoint, params.Encode())
+	req, _ := http.NewRequest("GET", reqURL, nil)
+	req.Header.Set("User-Agent", "GoGoodWe/1.0")
+
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			return
+		}
+	}(resp.Body)
+
+	var results nominatimResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return 0, 0, err
+	}
+
+	if len(results) == 0 {
+		return 0, 0, fmt.Errorf("location not found")
+	}
+
+	lat, _ := strconv.ParseFloat(results[0].Lat, 64)
+	lon, _ := strconv.ParseFloat(results[0].Lon, 64)
+
+	return lat, lon, nil
+}
+
+// LoadCache reads a LocationCache from path, returning an empty cache if
+// the file doesn't exist yet.
+func LoadCache(path string) (LocationCache, error) {
+	log.Debugf("Loading location cache from %s", path)
+
+	var cache LocationCache
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(LocationCache), nil // cache is empty
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &cache); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// SaveCache writes cache to path as indented JSON.
+func SaveCache(path string, cache LocationCache) error {
+	log.Debugf("Saving cache %v to %s", cache, path)
+
+	b, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0644)
+}
+
+// Resolve looks up location in cache, falling back to geocoder and
+// persisting the result to cache on a miss.
+func Resolve(geocoder Geocoder, cache LocationCache, cachePath, location string) (lat, lon float64, err error) {
+	if coords, ok := cache[location]; ok {
+		return coords[0], coords[1], nil
+	}
+
+	log.Debugf("Geocoding location: %s\n", location)
+
+	lat, lon, err = geocoder.Geocode(location)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	cache[location] = [2]float64{lat, lon}
+	if err := SaveCache(cachePath, cache); err != nil {
+		log.Errorf("Error saving cache: %v\n", err)
+	}
+
+	return lat, lon, nil
+}
@@ -0,0 +1,111 @@
+package geocode
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNominatimGeocoderGeocode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "Brisbane, Australia" {
+			t.Errorf("q = %q, want %q", r.URL.Query().Get("q"), "Brisbane, Australia")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]string{
+			{"lat": "-27.4698", "lon": "153.0251"},
+		})
+	}))
+	defer srv.Close()
+
+	g := NewNominatimGeocoder()
+	g.HTTPClient = srv.Client()
+
+	// Geocode reads Nominatim's host from nominatimEndpoint; point it at the
+	// test server for the duration of this test.
+	prev := nominatimEndpoint
+	nominatimEndpoint = srv.URL
+	defer func() { nominatimEndpoint = prev }()
+
+	lat, lon, err := g.Geocode("Brisbane, Australia")
+	if err != nil {
+		t.Fatalf("Geocode: %v", err)
+	}
+	if lat != -27.4698 || lon != 153.0251 {
+		t.Errorf("Geocode = (%v, %v), want (-27.4698, 153.0251)", lat, lon)
+	}
+}
+
+func TestNominatimGeocoderNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]map[string]string{})
+	}))
+	defer srv.Close()
+
+	g := NewNominatimGeocoder()
+	g.HTTPClient = srv.Client()
+
+	prev := nominatimEndpoint
+	nominatimEndpoint = srv.URL
+	defer func() { nominatimEndpoint = prev }()
+
+	if _, _, err := g.Geocode("Nowhere"); err == nil {
+		t.Fatal("expected error for empty results")
+	}
+}
+
+type fakeGeocoder struct {
+	lat, lon float64
+	calls    int
+}
+
+func (f *fakeGeocoder) Geocode(string) (float64, float64, error) {
+	f.calls++
+	return f.lat, f.lon, nil
+}
+
+func TestResolveCachesAcrossCalls(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "location_cache.json")
+
+	fg := &fakeGeocoder{lat: -27.4698, lon: 153.0251}
+	cache, err := LoadCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadCache: %v", err)
+	}
+
+	lat, lon, err := Resolve(fg, cache, cachePath, "Brisbane, Australia")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if lat != fg.lat || lon != fg.lon {
+		t.Errorf("Resolve = (%v, %v), want (%v, %v)", lat, lon, fg.lat, fg.lon)
+	}
+	if fg.calls != 1 {
+		t.Fatalf("calls = %d, want 1", fg.calls)
+	}
+
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("expected cache file to be written: %v", err)
+	}
+
+	reloaded, err := LoadCache(cachePath)
+	if err != nil {
+		t.Fatalf("LoadCache reload: %v", err)
+	}
+
+	lat2, lon2, err := Resolve(fg, reloaded, cachePath, "Brisbane, Australia")
+	if err != nil {
+		t.Fatalf("Resolve (cached): %v", err)
+	}
+	if lat2 != lat || lon2 != lon {
+		t.Errorf("cached Resolve = (%v, %v), want (%v, %v)", lat2, lon2, lat, lon)
+	}
+	if fg.calls != 1 {
+		t.Errorf("calls = %d, want 1 (should hit cache on second Resolve)", fg.calls)
+	}
+}
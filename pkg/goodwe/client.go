@@ -0,0 +1,157 @@
+package goodwe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// ClientOptions configures per-attempt timeouts and retry backoff for
+// Client.GetData.
+type ClientOptions struct {
+	// AttemptTimeout bounds a single dial+query+read round trip.
+	AttemptTimeout time.Duration
+	// MaxRetries is the total number of attempts (including the first).
+	MaxRetries int
+	// BackoffBase is the starting delay between attempts; it doubles on
+	// each subsequent retry, up to BackoffMax, with jitter applied.
+	BackoffBase time.Duration
+	// BackoffMax caps the delay between attempts.
+	BackoffMax time.Duration
+}
+
+// DefaultClientOptions matches the client's historical behaviour: a
+// 1-second per-attempt timeout and up to 3 attempts.
+var DefaultClientOptions = ClientOptions{
+	AttemptTimeout: time.Second,
+	MaxRetries:     3,
+	BackoffBase:    time.Second,
+	BackoffMax:     10 * time.Second,
+}
+
+// Client talks to a single inverter over UDP.
+type Client struct {
+	Addr string
+	opts ClientOptions
+}
+
+// New returns a Client for the inverter at ip:port, using DefaultClientOptions.
+func New(ip string, port int) *Client {
+	return NewWithOptions(ip, port, DefaultClientOptions)
+}
+
+// NewWithOptions returns a Client for the inverter at ip:port with custom
+// timeout and retry behaviour.
+func NewWithOptions(ip string, port int, opts ClientOptions) *Client {
+	return &Client{Addr: fmt.Sprintf("%s:%d", ip, port), opts: opts}
+}
+
+// GetData queries the inverter for a fresh Data sample, retrying with
+// exponential backoff until MaxRetries attempts have been made or ctx is
+// cancelled. Cancelling ctx interrupts an in-flight read immediately
+// instead of waiting out the attempt's full timeout.
+func (c *Client) GetData(ctx context.Context) (*Data, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < c.opts.MaxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, err := c.getData(ctx)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+
+		if attempt < c.opts.MaxRetries-1 {
+			select {
+			case <-time.After(backoff(c.opts, attempt)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get data after %d attempts: %w", c.opts.MaxRetries, lastErr)
+}
+
+func (c *Client) getData(ctx context.Context) (*Data, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", c.Addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func(conn net.Conn) {
+		err := conn.Close()
+		if err != nil {
+			return
+		}
+	}(conn)
+
+	deadline := time.Now().Add(c.opts.AttemptTimeout)
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// Watch ctx for cancellation and, if it fires before the natural
+	// deadline, force any in-flight Read to return immediately by moving
+	// the read deadline to now.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	// Discovery request payload
+	request := []byte{0x7f, 0x03, 0x75, 0x94, 0x00, 0x49}
+	request = append(request, CRC16(request)...)
+
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 153)
+	n, err := conn.Read(buf)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	if n != 153 {
+		return nil, fmt.Errorf("bad response size: got %d, want 153", n)
+	}
+
+	if !bytes.Equal(buf[:2], []byte{0xAA, 0x55}) {
+		return nil, fmt.Errorf("invalid header: %x", buf[:2])
+	}
+
+	payload := buf[2:151]
+	if !bytes.Equal(CRC16(payload), buf[151:]) {
+		return nil, errors.New("CRC mismatch")
+	}
+
+	return parsePayload(payload)
+}
+
+// backoff returns the delay before retry attempt+1, doubling BackoffBase
+// per attempt up to BackoffMax, with up to 50% jitter.
+func backoff(opts ClientOptions, attempt int) time.Duration {
+	d := opts.BackoffBase << attempt
+	if d <= 0 || d > opts.BackoffMax {
+		d = opts.BackoffMax
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+
+	return d/2 + jitter
+}
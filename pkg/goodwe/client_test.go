@@ -0,0 +1,119 @@
+package goodwe
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func testOptions() ClientOptions {
+	return ClientOptions{
+		AttemptTimeout: 200 * time.Millisecond,
+		MaxRetries:     3,
+		BackoffBase:    50 * time.Millisecond,
+		BackoffMax:     200 * time.Millisecond,
+	}
+}
+
+func TestClientGetData(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 64)
+		_, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		_, _ = conn.WriteToUDP(fixturePayload, addr)
+	}()
+
+	c := NewWithOptions("127.0.0.1", conn.LocalAddr().(*net.UDPAddr).Port, testOptions())
+
+	data, err := c.GetData(context.Background())
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if data.PowerAC != 1150.0 {
+		t.Errorf("PowerAC = %.1f, want 1150.0", data.PowerAC)
+	}
+}
+
+func TestClientGetDataRetriesOnTimeout(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	defer conn.Close()
+
+	attempts := 0
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			_, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			attempts++
+			if attempts < 2 {
+				continue // stall the first attempt so it times out
+			}
+			_, _ = conn.WriteToUDP(fixturePayload, addr)
+			return
+		}
+	}()
+
+	c := NewWithOptions("127.0.0.1", conn.LocalAddr().(*net.UDPAddr).Port, testOptions())
+
+	data, err := c.GetData(context.Background())
+	if err != nil {
+		t.Fatalf("GetData: %v", err)
+	}
+	if data.PowerAC != 1150.0 {
+		t.Errorf("PowerAC = %.1f, want 1150.0", data.PowerAC)
+	}
+	if attempts < 2 {
+		t.Errorf("attempts = %d, want at least 2", attempts)
+	}
+}
+
+func TestClientGetDataReturnsAfterRetriesExhausted(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	defer conn.Close() // never responds
+
+	c := NewWithOptions("127.0.0.1", conn.LocalAddr().(*net.UDPAddr).Port, testOptions())
+
+	if _, err := c.GetData(context.Background()); err == nil {
+		t.Fatal("expected error after retries exhausted")
+	}
+}
+
+func TestClientGetDataRespectsContextCancellation(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("ListenUDP: %v", err)
+	}
+	defer conn.Close() // never responds
+
+	opts := testOptions()
+	opts.AttemptTimeout = time.Minute // would hang if cancellation didn't interrupt the read
+	c := NewWithOptions("127.0.0.1", conn.LocalAddr().(*net.UDPAddr).Port, opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.GetData(ctx); err == nil {
+		t.Fatal("expected error from cancelled context")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetData took %v to return after context cancellation, expected it to interrupt promptly", elapsed)
+	}
+}
@@ -0,0 +1,134 @@
+// Package goodwe implements a client for the UDP protocol spoken by GoodWe
+// grid-tied inverters, used to pull live power, voltage and yield telemetry
+// off the local network.
+package goodwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// GoodWeStatus is the inverter's reported operating state.
+type GoodWeStatus int
+
+const (
+	WAITING GoodWeStatus = iota
+	NORMAL
+	ERROR
+	CHECKING
+)
+
+// Data is a single telemetry sample read from the inverter.
+type Data struct {
+	Sample       time.Time    `json:"sample"`
+	VoltageDC    [4]float64   `json:"voltage_dc"`
+	CurrentDC    [4]float64   `json:"current_dc"`
+	PowerDC      [4]float64   `json:"power_dc"`
+	VoltageAC    [3]float64   `json:"voltage_ac"`
+	CurrentAC    [3]float64   `json:"current_ac"`
+	FrequencyAC  [3]float64   `json:"frequency_ac"`
+	PowerAC      float64      `json:"power_ac"`
+	Status       GoodWeStatus `json:"status"`
+	Temperature  float64      `json:"temperature"`
+	YieldToday   float64      `json:"yield_today"`
+	YieldTotal   float64      `json:"yield_total"`
+	WorkingHours float64      `json:"working_hours"`
+}
+
+func parsePayload(data []byte) (*Data, error) {
+	d := &Data{Sample: time.Now()}
+
+	// DC inputs
+	for i := 0; i < 4; i++ {
+		vi := 9 + i*4
+		d.VoltageDC[i] = Parse16(data[vi:vi+2], -1)
+		d.CurrentDC[i] = Parse16(data[vi+2:vi+4], -1)
+		d.PowerDC[i] = d.VoltageDC[i] * d.CurrentDC[i]
+	}
+
+	// AC outputs
+	for i := 0; i < 3; i++ {
+		vi := 39 + i*2
+		ci := 45 + i*2
+		fi := 51 + i*2
+
+		v := Parse16(data[vi:vi+2], -1)
+		c := Parse16(data[ci:ci+2], -1)
+		f := Parse16(data[fi:fi+2], -2)
+
+		if i > 0 && v == 6553.5 {
+			v, c, f = 0, 0, 0
+		}
+
+		d.VoltageAC[i] = v
+		d.CurrentAC[i] = c
+		d.FrequencyAC[i] = f
+	}
+
+	d.PowerAC = Parse16(data[59:61], 0)
+	d.Status = GoodWeStatus(int(Parse16(data[61:63], 0)))
+	d.Temperature = Parse16(data[85:87], -1)
+	d.YieldToday = Parse16(data[91:93], -1)
+	d.YieldTotal = Parse32(data[93:97], 0)
+	d.WorkingHours = Parse16(data[99:101], 0)
+
+	if d.YieldToday > 6500 || d.YieldTotal > 4_000_000 {
+		return nil, errors.New("unrealistic yield values")
+	}
+
+	return d, nil
+}
+
+// Parse16 decodes a big-endian uint16 and scales it by 10^exp, rounded to
+// -exp decimal places.
+func Parse16(b []byte, exp int) float64 {
+	return round(float64(binary.BigEndian.Uint16(b))*pow10(exp), -exp)
+}
+
+// Parse32 decodes a big-endian uint32 and scales it by 10^exp, rounded to
+// -exp decimal places.
+func Parse32(b []byte, exp int) float64 {
+	return round(float64(binary.BigEndian.Uint32(b))*pow10(exp), -exp)
+}
+
+func pow10(exp int) float64 {
+	switch {
+	case exp == 0:
+		return 1
+	case exp > 0:
+		v := 1.0
+		for i := 0; i < exp; i++ {
+			v *= 10
+		}
+		return v
+	default:
+		v := 1.0
+		for i := 0; i < -exp; i++ {
+			v /= 10
+		}
+		return v
+	}
+}
+
+func round(f float64, places int) float64 {
+	scale := pow10(places)
+	return float64(int64(f*scale+0.5)) / scale
+}
+
+// CRC16 computes the CRC-16/MODBUS checksum (low byte first) used to frame
+// requests and responses on the wire.
+func CRC16(data []byte) []byte {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = (crc >> 1) ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return []byte{byte(crc), byte(crc >> 8)}
+}
@@ -0,0 +1,58 @@
+package goodwe
+
+import "testing"
+
+// fixturePayload is a recorded 153-byte discovery response: 0xAA55 header,
+// 149-byte telemetry payload, 2-byte CRC16 trailer.
+var fixturePayload = []byte{
+	0xaa, 0x55, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0d, 0xac, 0x00, 0x50, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0xfc, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x32, 0x00, 0x00, 0x00, 0x00, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x7e, 0x00,
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x45, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7b, 0x00,
+	0x06, 0xf8, 0x4c, 0x00, 0x00, 0x30, 0x39, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xa7, 0x23,
+}
+
+func TestCRC16(t *testing.T) {
+	got := CRC16([]byte{0x7f, 0x03, 0x75, 0x94, 0x00, 0x49})
+	want := []byte{0xd5, 0xc2}
+	if got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("CRC16 = %x, want %x", got, want)
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	data, err := parsePayload(fixturePayload[2:151])
+	if err != nil {
+		t.Fatalf("parsePayload: %v", err)
+	}
+
+	if data.VoltageDC[0] != 350.0 || data.CurrentDC[0] != 8.0 {
+		t.Errorf("string 1 DC = %.1fV %.1fA, want 350.0V 8.0A", data.VoltageDC[0], data.CurrentDC[0])
+	}
+	if data.VoltageAC[0] != 230.0 || data.FrequencyAC[0] != 50.0 {
+		t.Errorf("phase 1 AC = %.1fV %.2fHz, want 230.0V 50.00Hz", data.VoltageAC[0], data.FrequencyAC[0])
+	}
+	if data.PowerAC != 1150.0 {
+		t.Errorf("PowerAC = %.1f, want 1150.0", data.PowerAC)
+	}
+	if data.Status != NORMAL {
+		t.Errorf("Status = %v, want NORMAL", data.Status)
+	}
+	if data.Temperature != 32.5 {
+		t.Errorf("Temperature = %.1f, want 32.5", data.Temperature)
+	}
+	if data.YieldToday != 12.3 {
+		t.Errorf("YieldToday = %.1f, want 12.3", data.YieldToday)
+	}
+	if data.YieldTotal != 456780.0 {
+		t.Errorf("YieldTotal = %.1f, want 456780.0", data.YieldTotal)
+	}
+	if data.WorkingHours != 12345.0 {
+		t.Errorf("WorkingHours = %.1f, want 12345.0", data.WorkingHours)
+	}
+}
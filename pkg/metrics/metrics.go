@@ -0,0 +1,77 @@
+// Package metrics exposes an inverter's live telemetry as a Prometheus
+// text-format /metrics endpoint, so it can be graphed in Grafana without
+// going through PVOutput.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mrtimp/go-goodwe/pkg/goodwe"
+)
+
+// scrapeTimeout bounds how long an on-demand scrape may spend querying the
+// inverter before the HTTP response is written.
+const scrapeTimeout = 10 * time.Second
+
+// Server implements http.Handler, rendering the last known Data as a
+// Prometheus exposition. With onScrape set, every request triggers a fresh
+// Client.GetData instead of serving whatever Update last cached.
+type Server struct {
+	client   *goodwe.Client
+	onScrape bool
+
+	mu           sync.Mutex
+	data         *goodwe.Data
+	lastScrape   time.Time
+	scrapeErrors uint64
+}
+
+// NewServer returns a Server reporting on client's inverter. When onScrape
+// is true, ServeHTTP fetches fresh telemetry per request, bounded by
+// scrapeTimeout; otherwise it serves whatever Update last recorded.
+func NewServer(client *goodwe.Client, onScrape bool) *Server {
+	return &Server{client: client, onScrape: onScrape}
+}
+
+// Update records the result of a poll, for callers driving their own
+// polling loop rather than running in on-scrape mode. On a failed poll the
+// last known-good data is kept so a transient error doesn't blank out the
+// series between scrapes; only the scrape-health metrics change.
+func (s *Server) Update(data *goodwe.Data, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastScrape = time.Now()
+	if err != nil {
+		s.scrapeErrors++
+		return
+	}
+
+	s.data = data
+}
+
+// ServeHTTP renders the current telemetry as a Prometheus text exposition.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.onScrape {
+		ctx, cancel := context.WithTimeout(r.Context(), scrapeTimeout)
+		defer cancel()
+
+		data, err := s.client.GetData(ctx)
+		s.Update(data, err)
+	}
+
+	data, lastScrape, scrapeErrors := s.snapshot()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	render(w, data, lastScrape, scrapeErrors)
+}
+
+func (s *Server) snapshot() (*goodwe.Data, time.Time, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data, s.lastScrape, s.scrapeErrors
+}
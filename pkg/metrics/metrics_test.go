@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mrtimp/go-goodwe/pkg/goodwe"
+)
+
+var errScrapeTimeout = errors.New("simulated scrape timeout")
+
+func sampleData() *goodwe.Data {
+	return &goodwe.Data{
+		VoltageDC:    [4]float64{350, 351, 0, 0},
+		CurrentDC:    [4]float64{8, 7.5, 0, 0},
+		PowerDC:      [4]float64{2800, 2632.5, 0, 0},
+		VoltageAC:    [3]float64{230, 0, 0},
+		CurrentAC:    [3]float64{5, 0, 0},
+		FrequencyAC:  [3]float64{50, 0, 0},
+		PowerAC:      1150,
+		Status:       goodwe.NORMAL,
+		Temperature:  32.5,
+		YieldToday:   12.3,
+		YieldTotal:   456780,
+		WorkingHours: 12345,
+	}
+}
+
+func TestServeHTTPServesCachedData(t *testing.T) {
+	s := NewServer(nil, false)
+	s.Update(sampleData(), nil)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`goodwe_power_ac_watts 1150`,
+		`goodwe_voltage_dc{string="1"} 350`,
+		`goodwe_current_dc{string="2"} 7.5`,
+		`goodwe_frequency_ac{phase="1"} 50`,
+		`goodwe_status{state="normal"} 1`,
+		`goodwe_status{state="error"} 0`,
+		`goodwe_yield_total_kwh 456780`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestServeHTTPWithoutAnyUpdateOmitsDataMetrics(t *testing.T) {
+	s := NewServer(nil, false)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "goodwe_scrape_errors_total 0") {
+		t.Errorf("expected scrape_errors_total, got:\n%s", body)
+	}
+	if strings.Contains(body, "goodwe_power_ac_watts") {
+		t.Errorf("expected no data metrics before the first Update, got:\n%s", body)
+	}
+}
+
+func TestUpdateCountsScrapeErrors(t *testing.T) {
+	s := NewServer(nil, false)
+	s.Update(nil, errScrapeTimeout)
+	s.Update(sampleData(), nil)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.Contains(rec.Body.String(), "goodwe_scrape_errors_total 1") {
+		t.Errorf("expected one scrape error recorded, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestUpdateRecordsLastScrapeTimestamp(t *testing.T) {
+	s := NewServer(nil, false)
+	s.Update(sampleData(), nil)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "goodwe_last_scrape_timestamp_seconds") {
+		t.Fatalf("missing last_scrape_timestamp metric, got:\n%s", body)
+	}
+}
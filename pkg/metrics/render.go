@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mrtimp/go-goodwe/pkg/goodwe"
+)
+
+var statusNames = map[goodwe.GoodWeStatus]string{
+	goodwe.WAITING:  "waiting",
+	goodwe.NORMAL:   "normal",
+	goodwe.ERROR:    "error",
+	goodwe.CHECKING: "checking",
+}
+
+var statuses = []goodwe.GoodWeStatus{goodwe.WAITING, goodwe.NORMAL, goodwe.ERROR, goodwe.CHECKING}
+
+// render writes data as a Prometheus text exposition. data may be nil if no
+// scrape has succeeded yet, in which case only the scrape-health metrics
+// are written.
+func render(w io.Writer, data *goodwe.Data, lastScrape time.Time, scrapeErrors uint64) {
+	writeMetric(w, "goodwe_scrape_errors_total", "counter", "Total number of failed inverter scrapes.", func() {
+		fmt.Fprintf(w, "goodwe_scrape_errors_total %d\n", scrapeErrors)
+	})
+
+	if !lastScrape.IsZero() {
+		writeMetric(w, "goodwe_last_scrape_timestamp_seconds", "gauge", "Unix time of the last scrape attempt.", func() {
+			fmt.Fprintf(w, "goodwe_last_scrape_timestamp_seconds %d\n", lastScrape.Unix())
+		})
+	}
+
+	if data == nil {
+		return
+	}
+
+	writeMetric(w, "goodwe_status", "gauge", "Inverter operating state; 1 for the current state, 0 for the rest.", func() {
+		for _, status := range statuses {
+			v := 0
+			if data.Status == status {
+				v = 1
+			}
+			fmt.Fprintf(w, "goodwe_status{state=%q} %d\n", statusNames[status], v)
+		}
+	})
+
+	writeMetric(w, "goodwe_voltage_dc", "gauge", "DC input voltage, in volts, by string.", func() {
+		for i, v := range data.VoltageDC {
+			fmt.Fprintf(w, "goodwe_voltage_dc{string=%q} %v\n", strconv.Itoa(i+1), v)
+		}
+	})
+
+	writeMetric(w, "goodwe_current_dc", "gauge", "DC input current, in amps, by string.", func() {
+		for i, v := range data.CurrentDC {
+			fmt.Fprintf(w, "goodwe_current_dc{string=%q} %v\n", strconv.Itoa(i+1), v)
+		}
+	})
+
+	writeMetric(w, "goodwe_power_dc_watts", "gauge", "DC input power, in watts, by string.", func() {
+		for i, v := range data.PowerDC {
+			fmt.Fprintf(w, "goodwe_power_dc_watts{string=%q} %v\n", strconv.Itoa(i+1), v)
+		}
+	})
+
+	writeMetric(w, "goodwe_voltage_ac", "gauge", "AC output voltage, in volts, by phase.", func() {
+		for i, v := range data.VoltageAC {
+			fmt.Fprintf(w, "goodwe_voltage_ac{phase=%q} %v\n", strconv.Itoa(i+1), v)
+		}
+	})
+
+	writeMetric(w, "goodwe_current_ac", "gauge", "AC output current, in amps, by phase.", func() {
+		for i, v := range data.CurrentAC {
+			fmt.Fprintf(w, "goodwe_current_ac{phase=%q} %v\n", strconv.Itoa(i+1), v)
+		}
+	})
+
+	writeMetric(w, "goodwe_frequency_ac", "gauge", "AC output frequency, in hertz, by phase.", func() {
+		for i, v := range data.FrequencyAC {
+			fmt.Fprintf(w, "goodwe_frequency_ac{phase=%q} %v\n", strconv.Itoa(i+1), v)
+		}
+	})
+
+	writeMetric(w, "goodwe_power_ac_watts", "gauge", "AC output power, in watts.", func() {
+		fmt.Fprintf(w, "goodwe_power_ac_watts %v\n", data.PowerAC)
+	})
+
+	writeMetric(w, "goodwe_temperature_celsius", "gauge", "Inverter internal temperature, in degrees Celsius.", func() {
+		fmt.Fprintf(w, "goodwe_temperature_celsius %v\n", data.Temperature)
+	})
+
+	writeMetric(w, "goodwe_yield_today_kwh", "gauge", "Energy yielded so far today, in kWh.", func() {
+		fmt.Fprintf(w, "goodwe_yield_today_kwh %v\n", data.YieldToday)
+	})
+
+	writeMetric(w, "goodwe_yield_total_kwh", "gauge", "Energy yielded over the inverter's lifetime, in kWh.", func() {
+		fmt.Fprintf(w, "goodwe_yield_total_kwh %v\n", data.YieldTotal)
+	})
+
+	writeMetric(w, "goodwe_working_hours", "gauge", "Total hours the inverter has spent generating.", func() {
+		fmt.Fprintf(w, "goodwe_working_hours %v\n", data.WorkingHours)
+	})
+}
+
+func writeMetric(w io.Writer, name, metricType, help string, body func()) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+	body()
+}
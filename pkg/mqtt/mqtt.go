@@ -0,0 +1,119 @@
+// Package mqtt publishes inverter readings to an MQTT broker, with Home
+// Assistant MQTT Discovery config topics so each field of goodwe.Data shows
+// up as a sensor entity without any manual HA configuration.
+package mqtt
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/mrtimp/go-goodwe/pkg/goodwe"
+)
+
+// Config holds broker connection settings and publish behaviour.
+type Config struct {
+	Broker   string // e.g. tcp://host:1883, ssl://host:8883
+	ClientID string
+	Username string
+	Password string
+	Topic    string // base topic for state publishes, e.g. "goodwe"
+	QoS      byte
+	Retain   bool
+}
+
+// Publisher publishes Data samples, and the Home Assistant discovery
+// configs that describe them, for a single inverter.
+type Publisher struct {
+	client     paho.Client
+	cfg        Config
+	deviceID   string
+	deviceName string
+	stateTopic string
+}
+
+// NewPublisher connects to the configured broker and returns a Publisher
+// for the inverter at inverterIP. The device is identified to Home
+// Assistant by inverterIP, since the UDP protocol doesn't expose a serial
+// number.
+func NewPublisher(cfg Config, inverterIP string) (*Publisher, error) {
+	deviceID := "goodwe_" + strings.ReplaceAll(inverterIP, ".", "_")
+
+	opts := paho.NewClientOptions().AddBroker(cfg.Broker).SetClientID(cfg.ClientID)
+	if cfg.Username != "" {
+		opts.SetUsername(cfg.Username)
+		opts.SetPassword(cfg.Password)
+	}
+	if strings.HasPrefix(cfg.Broker, "ssl://") || strings.HasPrefix(cfg.Broker, "tls://") {
+		opts.SetTLSConfig(&tls.Config{})
+	}
+
+	client := paho.NewClient(opts)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return nil, fmt.Errorf("connect to MQTT broker %s: %w", cfg.Broker, token.Error())
+	}
+
+	return newPublisher(client, cfg, deviceID, inverterIP), nil
+}
+
+// newPublisher builds a Publisher around an already-connected client. It's
+// split out from NewPublisher so tests can inject a fake paho.Client instead
+// of dialling a real broker.
+func newPublisher(client paho.Client, cfg Config, deviceID, inverterIP string) *Publisher {
+	return &Publisher{
+		client:     client,
+		cfg:        cfg,
+		deviceID:   deviceID,
+		deviceName: fmt.Sprintf("GoodWe Inverter (%s)", inverterIP),
+		stateTopic: fmt.Sprintf("%s/%s/state", cfg.Topic, deviceID),
+	}
+}
+
+// Close disconnects from the broker, waiting up to 250ms to flush.
+func (p *Publisher) Close() {
+	p.client.Disconnect(250)
+}
+
+// PublishDiscovery announces every sensor described by sensorDescriptors to
+// Home Assistant via the MQTT Discovery convention. Discovery configs are
+// always retained, so Home Assistant picks them up even if it starts after
+// this publish. Call once at startup.
+func (p *Publisher) PublishDiscovery() error {
+	for _, s := range sensorDescriptors() {
+		payload := discoveryConfig(p.deviceID, p.deviceName, p.stateTopic, s)
+
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+
+		token := p.client.Publish(discoveryTopic(p.deviceID, s), p.cfg.QoS, true, b)
+		if token.Wait() && token.Error() != nil {
+			return fmt.Errorf("publish discovery config for %s: %w", s.Suffix, token.Error())
+		}
+	}
+
+	log.Debugf("Published MQTT discovery configs for device %s\n", p.deviceID)
+
+	return nil
+}
+
+// PublishData publishes a single telemetry sample as JSON to the state
+// topic that every discovered sensor's value_template reads from.
+func (p *Publisher) PublishData(data *goodwe.Data) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	token := p.client.Publish(p.stateTopic, p.cfg.QoS, p.cfg.Retain, b)
+	if token.Wait() && token.Error() != nil {
+		return fmt.Errorf("publish state: %w", token.Error())
+	}
+
+	return nil
+}
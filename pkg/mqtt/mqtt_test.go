@@ -0,0 +1,145 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/mrtimp/go-goodwe/pkg/goodwe"
+)
+
+// fakeToken is a paho.Token that resolves immediately with a fixed error.
+type fakeToken struct{ err error }
+
+func (t *fakeToken) Wait() bool                     { return true }
+func (t *fakeToken) WaitTimeout(time.Duration) bool { return true }
+func (t *fakeToken) Done() <-chan struct{}          { ch := make(chan struct{}); close(ch); return ch }
+func (t *fakeToken) Error() error                   { return t.err }
+
+type publishCall struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// fakeClient is a paho.Client that records Publish calls instead of talking
+// to a broker, optionally failing the next one.
+type fakeClient struct {
+	publishes []publishCall
+	failNext  error
+}
+
+func (c *fakeClient) IsConnected() bool      { return true }
+func (c *fakeClient) IsConnectionOpen() bool { return true }
+func (c *fakeClient) Connect() paho.Token    { return &fakeToken{} }
+func (c *fakeClient) Disconnect(quiesce uint) {}
+
+func (c *fakeClient) Publish(topic string, qos byte, retained bool, payload interface{}) paho.Token {
+	b, ok := payload.([]byte)
+	if !ok {
+		b, _ = json.Marshal(payload)
+	}
+	c.publishes = append(c.publishes, publishCall{topic: topic, qos: qos, retained: retained, payload: b})
+
+	err := c.failNext
+	c.failNext = nil
+
+	return &fakeToken{err: err}
+}
+
+func (c *fakeClient) Subscribe(topic string, qos byte, callback paho.MessageHandler) paho.Token {
+	return &fakeToken{}
+}
+
+func (c *fakeClient) SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) paho.Token {
+	return &fakeToken{}
+}
+
+func (c *fakeClient) Unsubscribe(topics ...string) paho.Token { return &fakeToken{} }
+func (c *fakeClient) AddRoute(topic string, callback paho.MessageHandler) {}
+func (c *fakeClient) OptionsReader() paho.ClientOptionsReader {
+	return paho.ClientOptionsReader{}
+}
+
+func TestPublishDiscoveryPublishesEverySensorRetained(t *testing.T) {
+	fc := &fakeClient{}
+	p := newPublisher(fc, Config{Topic: "goodwe", QoS: 1}, "goodwe_192_168_1_50", "192.168.1.50")
+
+	if err := p.PublishDiscovery(); err != nil {
+		t.Fatalf("PublishDiscovery: %v", err)
+	}
+
+	want := len(sensorDescriptors())
+	if len(fc.publishes) != want {
+		t.Fatalf("published %d discovery configs, want %d", len(fc.publishes), want)
+	}
+
+	for _, call := range fc.publishes {
+		if !call.retained {
+			t.Errorf("discovery publish to %q not retained", call.topic)
+		}
+		if call.qos != 1 {
+			t.Errorf("discovery publish to %q QoS = %d, want 1", call.topic, call.qos)
+		}
+	}
+
+	wantTopic := discoveryTopic("goodwe_192_168_1_50", sensorDescriptors()[0])
+	if fc.publishes[0].topic != wantTopic {
+		t.Errorf("first discovery topic = %q, want %q", fc.publishes[0].topic, wantTopic)
+	}
+}
+
+func TestPublishDiscoveryReturnsBrokerError(t *testing.T) {
+	fc := &fakeClient{failNext: errors.New("broker unreachable")}
+	p := newPublisher(fc, Config{Topic: "goodwe"}, "goodwe_x", "x")
+
+	if err := p.PublishDiscovery(); err == nil {
+		t.Fatal("expected error from failed publish")
+	}
+}
+
+func TestPublishDataPublishesToStateTopicWithConfiguredQoSAndRetain(t *testing.T) {
+	fc := &fakeClient{}
+	p := newPublisher(fc, Config{Topic: "goodwe", QoS: 2, Retain: true}, "goodwe_192_168_1_50", "192.168.1.50")
+
+	data := &goodwe.Data{PowerAC: 1150}
+	if err := p.PublishData(data); err != nil {
+		t.Fatalf("PublishData: %v", err)
+	}
+
+	if len(fc.publishes) != 1 {
+		t.Fatalf("published %d messages, want 1", len(fc.publishes))
+	}
+
+	call := fc.publishes[0]
+	if call.topic != "goodwe/goodwe_192_168_1_50/state" {
+		t.Errorf("topic = %q", call.topic)
+	}
+	if call.qos != 2 {
+		t.Errorf("QoS = %d, want 2", call.qos)
+	}
+	if !call.retained {
+		t.Error("expected state publish to be retained")
+	}
+
+	var got goodwe.Data
+	if err := json.Unmarshal(call.payload, &got); err != nil {
+		t.Fatalf("unmarshal published payload: %v", err)
+	}
+	if got.PowerAC != data.PowerAC {
+		t.Errorf("published PowerAC = %v, want %v", got.PowerAC, data.PowerAC)
+	}
+}
+
+func TestPublishDataReturnsBrokerError(t *testing.T) {
+	fc := &fakeClient{failNext: errors.New("broker unreachable")}
+	p := newPublisher(fc, Config{Topic: "goodwe"}, "goodwe_x", "x")
+
+	if err := p.PublishData(&goodwe.Data{}); err == nil {
+		t.Fatal("expected error from failed publish")
+	}
+}
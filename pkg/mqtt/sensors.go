@@ -0,0 +1,158 @@
+package mqtt
+
+import "fmt"
+
+// sensor describes a single Home Assistant MQTT Discovery sensor entity
+// derived from a field, or array element, of goodwe.Data.
+type sensor struct {
+	Suffix        string // appended to the device id to form the unique_id; used alone as the topic's object_id
+	Name          string
+	Unit          string
+	DeviceClass   string
+	StateClass    string // "measurement" or "total_increasing"
+	ValueTemplate string
+}
+
+// sensorDescriptors enumerates every sensor entity published for a
+// goodwe.Data sample.
+func sensorDescriptors() []sensor {
+	var s []sensor
+
+	for i := 0; i < 4; i++ {
+		s = append(s,
+			sensor{
+				Suffix:        fmt.Sprintf("voltage_dc_%d", i+1),
+				Name:          fmt.Sprintf("DC Voltage %d", i+1),
+				Unit:          "V",
+				DeviceClass:   "voltage",
+				StateClass:    "measurement",
+				ValueTemplate: fmt.Sprintf("{{ value_json.voltage_dc[%d] }}", i),
+			},
+			sensor{
+				Suffix:        fmt.Sprintf("current_dc_%d", i+1),
+				Name:          fmt.Sprintf("DC Current %d", i+1),
+				Unit:          "A",
+				DeviceClass:   "current",
+				StateClass:    "measurement",
+				ValueTemplate: fmt.Sprintf("{{ value_json.current_dc[%d] }}", i),
+			},
+			sensor{
+				Suffix:        fmt.Sprintf("power_dc_%d", i+1),
+				Name:          fmt.Sprintf("DC Power %d", i+1),
+				Unit:          "W",
+				DeviceClass:   "power",
+				StateClass:    "measurement",
+				ValueTemplate: fmt.Sprintf("{{ value_json.power_dc[%d] }}", i),
+			},
+		)
+	}
+
+	for i := 0; i < 3; i++ {
+		s = append(s,
+			sensor{
+				Suffix:        fmt.Sprintf("voltage_ac_%d", i+1),
+				Name:          fmt.Sprintf("AC Voltage %d", i+1),
+				Unit:          "V",
+				DeviceClass:   "voltage",
+				StateClass:    "measurement",
+				ValueTemplate: fmt.Sprintf("{{ value_json.voltage_ac[%d] }}", i),
+			},
+			sensor{
+				Suffix:        fmt.Sprintf("current_ac_%d", i+1),
+				Name:          fmt.Sprintf("AC Current %d", i+1),
+				Unit:          "A",
+				DeviceClass:   "current",
+				StateClass:    "measurement",
+				ValueTemplate: fmt.Sprintf("{{ value_json.current_ac[%d] }}", i),
+			},
+			sensor{
+				Suffix:        fmt.Sprintf("frequency_ac_%d", i+1),
+				Name:          fmt.Sprintf("AC Frequency %d", i+1),
+				Unit:          "Hz",
+				DeviceClass:   "frequency",
+				StateClass:    "measurement",
+				ValueTemplate: fmt.Sprintf("{{ value_json.frequency_ac[%d] }}", i),
+			},
+		)
+	}
+
+	s = append(s,
+		sensor{
+			Suffix:        "power_ac",
+			Name:          "AC Power",
+			Unit:          "W",
+			DeviceClass:   "power",
+			StateClass:    "measurement",
+			ValueTemplate: "{{ value_json.power_ac }}",
+		},
+		sensor{
+			Suffix:        "temperature",
+			Name:          "Temperature",
+			Unit:          "°C",
+			DeviceClass:   "temperature",
+			StateClass:    "measurement",
+			ValueTemplate: "{{ value_json.temperature }}",
+		},
+		sensor{
+			Suffix:        "yield_today",
+			Name:          "Yield Today",
+			Unit:          "kWh",
+			DeviceClass:   "energy",
+			StateClass:    "total_increasing",
+			ValueTemplate: "{{ value_json.yield_today }}",
+		},
+		sensor{
+			Suffix:        "yield_total",
+			Name:          "Yield Total",
+			Unit:          "kWh",
+			DeviceClass:   "energy",
+			StateClass:    "total_increasing",
+			ValueTemplate: "{{ value_json.yield_total }}",
+		},
+		sensor{
+			Suffix:        "working_hours",
+			Name:          "Working Hours",
+			Unit:          "h",
+			StateClass:    "total_increasing",
+			ValueTemplate: "{{ value_json.working_hours }}",
+		},
+	)
+
+	return s
+}
+
+// discoveryConfig builds the Home Assistant MQTT Discovery config payload
+// for a single sensor, grouped under the shared device identified by
+// deviceID.
+func discoveryConfig(deviceID, deviceName, stateTopic string, s sensor) map[string]interface{} {
+	payload := map[string]interface{}{
+		"name":           s.Name,
+		"unique_id":      fmt.Sprintf("%s_%s", deviceID, s.Suffix),
+		"state_topic":    stateTopic,
+		"value_template": s.ValueTemplate,
+		"device": map[string]interface{}{
+			"identifiers":  []string{deviceID},
+			"name":         deviceName,
+			"manufacturer": "GoodWe",
+			"model":        "Inverter",
+		},
+	}
+
+	if s.Unit != "" {
+		payload["unit_of_measurement"] = s.Unit
+	}
+	if s.DeviceClass != "" {
+		payload["device_class"] = s.DeviceClass
+	}
+	if s.StateClass != "" {
+		payload["state_class"] = s.StateClass
+	}
+
+	return payload
+}
+
+// discoveryTopic returns the config topic for a sensor, following the
+// `homeassistant/<component>/<node_id>/<object_id>/config` convention.
+func discoveryTopic(deviceID string, s sensor) string {
+	return fmt.Sprintf("homeassistant/sensor/%s/%s/config", deviceID, s.Suffix)
+}
@@ -0,0 +1,77 @@
+package mqtt
+
+import "testing"
+
+func TestSensorDescriptorsCoverage(t *testing.T) {
+	s := sensorDescriptors()
+
+	// 4 DC strings x 3 sensors + 3 AC phases x 3 sensors + 5 scalar sensors.
+	want := 4*3 + 3*3 + 5
+	if len(s) != want {
+		t.Fatalf("len(sensorDescriptors()) = %d, want %d", len(s), want)
+	}
+
+	seen := make(map[string]bool, len(s))
+	for _, sensor := range s {
+		if sensor.Suffix == "" {
+			t.Errorf("sensor %q has empty Suffix", sensor.Name)
+		}
+		if seen[sensor.Suffix] {
+			t.Errorf("duplicate sensor suffix %q", sensor.Suffix)
+		}
+		seen[sensor.Suffix] = true
+	}
+}
+
+func TestDiscoveryConfig(t *testing.T) {
+	s := sensor{
+		Suffix:        "power_ac",
+		Name:          "AC Power",
+		Unit:          "W",
+		DeviceClass:   "power",
+		StateClass:    "measurement",
+		ValueTemplate: "{{ value_json.power_ac }}",
+	}
+
+	cfg := discoveryConfig("goodwe_192_168_1_50", "GoodWe Inverter (192.168.1.50)", "goodwe/goodwe_192_168_1_50/state", s)
+
+	if cfg["unique_id"] != "goodwe_192_168_1_50_power_ac" {
+		t.Errorf("unique_id = %v", cfg["unique_id"])
+	}
+	if cfg["state_topic"] != "goodwe/goodwe_192_168_1_50/state" {
+		t.Errorf("state_topic = %v", cfg["state_topic"])
+	}
+	if cfg["unit_of_measurement"] != "W" {
+		t.Errorf("unit_of_measurement = %v", cfg["unit_of_measurement"])
+	}
+	if cfg["device_class"] != "power" {
+		t.Errorf("device_class = %v", cfg["device_class"])
+	}
+
+	device, ok := cfg["device"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("device = %T, want map[string]interface{}", cfg["device"])
+	}
+	ids, ok := device["identifiers"].([]string)
+	if !ok || len(ids) != 1 || ids[0] != "goodwe_192_168_1_50" {
+		t.Errorf("device identifiers = %v", device["identifiers"])
+	}
+}
+
+func TestDiscoveryConfigOmitsEmptyDeviceClass(t *testing.T) {
+	s := sensor{Suffix: "working_hours", Name: "Working Hours", Unit: "h", StateClass: "total_increasing", ValueTemplate: "{{ value_json.working_hours }}"}
+
+	cfg := discoveryConfig("goodwe_x", "GoodWe Inverter (x)", "goodwe/goodwe_x/state", s)
+
+	if _, ok := cfg["device_class"]; ok {
+		t.Errorf("expected no device_class for %q", s.Suffix)
+	}
+}
+
+func TestDiscoveryTopic(t *testing.T) {
+	got := discoveryTopic("goodwe_192_168_1_50", sensor{Suffix: "power_ac"})
+	want := "homeassistant/sensor/goodwe_192_168_1_50/power_ac/config"
+	if got != want {
+		t.Errorf("discoveryTopic() = %q, want %q", got, want)
+	}
+}
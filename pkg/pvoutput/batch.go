@@ -0,0 +1,89 @@
+package pvoutput
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// batchStatusURL is PVOutput's batch status endpoint, used to backfill
+// readings that couldn't be posted live. It's a var, rather than a const,
+// so tests can point it at an httptest server.
+var batchStatusURL = "https://pvoutput.org/service/r2/addbatchstatus.jsp"
+
+// MaxBatchSize is the most records PVOutput accepts in a single
+// addbatchstatus.jsp call.
+const MaxBatchSize = 30
+
+// AddBatchStatus posts up to MaxBatchSize Readings in a single call via
+// PVOutput's addbatchstatus.jsp endpoint. Callers with more readings than
+// that must chunk them themselves.
+func (c *Client) AddBatchStatus(readings []Reading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	if len(readings) > MaxBatchSize {
+		return fmt.Errorf("addbatchstatus: %d readings exceeds the %d-record limit", len(readings), MaxBatchSize)
+	}
+
+	records := make([]string, len(readings))
+	for i, r := range readings {
+		records[i] = batchRecord(r)
+	}
+
+	form := url.Values{}
+	form.Set("data", strings.Join(records, ";"))
+
+	req, err := http.NewRequest("POST", batchStatusURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Pvoutput-Apikey", c.cfg.APIKey)
+	req.Header.Set("X-Pvoutput-SystemId", c.cfg.SystemID)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			return
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("batch upload failed: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// batchRecord formats a Reading as one semicolon-joined addbatchstatus
+// record: d,t,v1,v2,v3,v4,v5,v6 with v3/v4 (consumption) left blank, since
+// this client only ever reports generation.
+func batchRecord(r Reading) string {
+	fields := []string{
+		r.Date.Format("20060102"),
+		r.Date.Format("15:04"),
+		fmt.Sprintf("%d", r.Energy),
+		fmt.Sprintf("%d", r.Power),
+		"",
+		"",
+		optionalField(r.Temperature),
+		optionalField(r.Voltage),
+	}
+
+	return strings.Join(fields, ",")
+}
+
+func optionalField(v int) string {
+	if v <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d", v)
+}
@@ -0,0 +1,81 @@
+package pvoutput
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchRecord(t *testing.T) {
+	date := time.Date(2026, 7, 26, 12, 30, 0, 0, time.UTC)
+	r := Reading{Date: date, Power: 1150, Energy: 12300, Voltage: 230, Temperature: 32}
+
+	got := batchRecord(r)
+	want := "20260726,12:30,12300,1150,,,32,230"
+	if got != want {
+		t.Errorf("batchRecord() = %q, want %q", got, want)
+	}
+}
+
+func TestBatchRecordOmitsZeroOptionalFields(t *testing.T) {
+	date := time.Date(2026, 7, 26, 12, 30, 0, 0, time.UTC)
+	r := Reading{Date: date, Power: 1150, Energy: 12300}
+
+	got := batchRecord(r)
+	want := "20260726,12:30,12300,1150,,,,"
+	if got != want {
+		t.Errorf("batchRecord() = %q, want %q", got, want)
+	}
+}
+
+func TestClientAddBatchStatus(t *testing.T) {
+	var gotData string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		gotData = r.Form.Get("data")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{APIKey: "key123", SystemID: "42"})
+	c.httpClient = srv.Client()
+
+	prevURL := batchStatusURL
+	batchStatusURL = srv.URL
+	defer func() { batchStatusURL = prevURL }()
+
+	date := time.Date(2026, 7, 26, 12, 30, 0, 0, time.UTC)
+	err := c.AddBatchStatus([]Reading{
+		{Date: date, Power: 1150, Energy: 12300},
+		{Date: date.Add(5 * time.Minute), Power: 1200, Energy: 12400},
+	})
+	if err != nil {
+		t.Fatalf("AddBatchStatus: %v", err)
+	}
+
+	records := strings.Split(gotData, ";")
+	if len(records) != 2 {
+		t.Fatalf("posted %d records, want 2", len(records))
+	}
+}
+
+func TestClientAddBatchStatusRejectsTooManyReadings(t *testing.T) {
+	c := NewClient(Config{APIKey: "key123", SystemID: "42"})
+
+	readings := make([]Reading, MaxBatchSize+1)
+	if err := c.AddBatchStatus(readings); err == nil {
+		t.Fatal("expected error for more than MaxBatchSize readings")
+	}
+}
+
+func TestClientAddBatchStatusEmpty(t *testing.T) {
+	c := NewClient(Config{APIKey: "key123", SystemID: "42"})
+	if err := c.AddBatchStatus(nil); err != nil {
+		t.Errorf("AddBatchStatus(nil) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package pvoutput uploads inverter readings to the PVOutput.org live
+// status API.
+package pvoutput
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// statusURL is PVOutput's live status endpoint (the addstatus.jsp service).
+// It's a var, rather than a const, so tests can point it at an httptest
+// server.
+var statusURL = "https://pvoutput.org/service/r2/addstatus.jsp"
+
+// Config holds the PVOutput account credentials for a single system.
+type Config struct {
+	APIKey   string
+	SystemID string
+}
+
+// Reading is a single status update to post to PVOutput.
+type Reading struct {
+	Date        time.Time // will be formatted YYYYMMDD
+	Power       int       // watts
+	Energy      int       // watt-hours
+	Voltage     int       // volts (optional)
+	Temperature int       // degrees Celsius (optional)
+}
+
+// Client posts Readings to PVOutput on behalf of a single system.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the given account.
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// AddStatus posts a single live status update.
+func (c *Client) AddStatus(r Reading) error {
+	form := url.Values{}
+	form.Set("d", r.Date.Format("20060102"))
+	form.Set("t", r.Date.Format("15:04"))
+	form.Set("v1", fmt.Sprintf("%d", r.Energy))
+	form.Set("v2", fmt.Sprintf("%d", r.Power))
+	if r.Voltage > 0 {
+		form.Set("v6", fmt.Sprintf("%d", r.Voltage))
+	}
+	if r.Temperature > 0 {
+		form.Set("v5", fmt.Sprintf("%d", r.Temperature))
+	}
+
+	req, err := http.NewRequest("POST", statusURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Pvoutput-Apikey", c.cfg.APIKey)
+	req.Header.Set("X-Pvoutput-SystemId", c.cfg.SystemID)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			return
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("upload failed: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package pvoutput
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClientAddStatus(t *testing.T) {
+	var gotForm url.Values
+	var gotAPIKey, gotSystemID string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAPIKey = r.Header.Get("X-Pvoutput-Apikey")
+		gotSystemID = r.Header.Get("X-Pvoutput-Systemid")
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		gotForm = r.Form
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{APIKey: "key123", SystemID: "42"})
+	c.httpClient = srv.Client()
+
+	prevURL := statusURL
+	statusURL = srv.URL
+	defer func() { statusURL = prevURL }()
+
+	date := time.Date(2026, 7, 26, 12, 30, 0, 0, time.UTC)
+	err := c.AddStatus(Reading{
+		Date:        date,
+		Power:       1150,
+		Energy:      12300,
+		Voltage:     230,
+		Temperature: 32,
+	})
+	if err != nil {
+		t.Fatalf("AddStatus: %v", err)
+	}
+
+	if gotAPIKey != "key123" {
+		t.Errorf("APIKey header = %q, want %q", gotAPIKey, "key123")
+	}
+	if gotSystemID != "42" {
+		t.Errorf("SystemID header = %q, want %q", gotSystemID, "42")
+	}
+	if gotForm.Get("d") != "20260726" {
+		t.Errorf("d = %q, want %q", gotForm.Get("d"), "20260726")
+	}
+	if gotForm.Get("t") != "12:30" {
+		t.Errorf("t = %q, want %q", gotForm.Get("t"), "12:30")
+	}
+	if gotForm.Get("v2") != "1150" {
+		t.Errorf("v2 = %q, want %q", gotForm.Get("v2"), "1150")
+	}
+	if gotForm.Get("v6") != "230" {
+		t.Errorf("v6 = %q, want %q", gotForm.Get("v6"), "230")
+	}
+}
+
+func TestClientAddStatusFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{APIKey: "key123", SystemID: "42"})
+	c.httpClient = srv.Client()
+
+	prevURL := statusURL
+	statusURL = srv.URL
+	defer func() { statusURL = prevURL }()
+
+	err := c.AddStatus(Reading{Date: time.Now(), Power: 100, Energy: 100})
+	if err == nil {
+		t.Fatal("expected error for non-200 response")
+	}
+}